@@ -0,0 +1,76 @@
+package problem
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxSynthesizedDetail is the maximum number of bytes of an unparseable
+// response body to keep when synthesizing a ProblemDetails for it.
+const maxSynthesizedDetail = 512
+
+// FromResponse is the client-side counterpart to Write. It inspects an
+// *http.Response for a problem details body and, if one is present, decodes
+// it and returns it as an error so that callers can use errors.As to recover
+// the original *ProblemDetails or *ValidationProblem. This lets the same
+// types be used on both ends of a REST call.
+//
+// A body is only treated as a *ValidationProblem if decoding it actually
+// populates invalid-params; a plain problem whose detail or title happens
+// to mention the phrase still decodes as a *ProblemDetails.
+//
+// If resp.StatusCode indicates success (< 400), FromResponse returns nil.
+// If the response can't be parsed as a problem details body -- because the
+// Content-Type isn't application/problem+json (or +xml) or the body isn't
+// valid -- FromResponse synthesizes a ProblemDetails from the status code,
+// status line, and a truncated copy of the body, so callers always get a
+// typed error back for a failed response.
+func FromResponse(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	mediatype, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err == nil {
+		switch mediatype {
+		case ContentProblemDetails:
+			vp := &ValidationProblem{}
+			if jerr := json.Unmarshal(body, vp); jerr == nil && len(vp.ValidationErrors) > 0 {
+				return vp
+			}
+			pd := &ProblemDetails{}
+			if jerr := json.Unmarshal(body, pd); jerr == nil {
+				return pd
+			}
+		case ContentProblemXML:
+			vp := &ValidationProblem{}
+			if xerr := xml.Unmarshal(body, vp); xerr == nil && len(vp.ValidationErrors) > 0 {
+				return vp
+			}
+			pd := &ProblemDetails{}
+			if xerr := xml.Unmarshal(body, pd); xerr == nil {
+				return pd
+			}
+		}
+	}
+	return synthesizeProblem(resp, body)
+}
+
+// synthesizeProblem builds a ProblemDetails from a response that didn't carry
+// a usable problem details body, so FromResponse always has something typed
+// to return.
+func synthesizeProblem(resp *http.Response, body []byte) *ProblemDetails {
+	detail := strings.TrimSpace(string(body))
+	if len(detail) > maxSynthesizedDetail {
+		detail = detail[:maxSynthesizedDetail] + "..."
+	}
+	pd := New(resp.StatusCode).WithDetail(detail)
+	if resp.Status != "" {
+		pd.Title = resp.Status
+	}
+	return pd
+}
@@ -0,0 +1,98 @@
+package problem_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lpar/problem"
+)
+
+func fakeResponse(status int, contentType string, body string) *http.Response {
+	h := http.Header{}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     h,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestFromResponseSuccess(t *testing.T) {
+	resp := fakeResponse(http.StatusOK, problem.ContentProblemDetails, `{"status":200}`)
+	if err := problem.FromResponse(resp); err != nil {
+		t.Errorf("expected nil error for status 200, got %v", err)
+	}
+}
+
+func TestFromResponseProblemDetails(t *testing.T) {
+	body := `{"status":404,"title":"Not Found","detail":"no such widget"}`
+	resp := fakeResponse(http.StatusNotFound, problem.ContentProblemDetails, body)
+	err := problem.FromResponse(resp)
+	var pd *problem.ProblemDetails
+	if !errors.As(err, &pd) {
+		t.Fatalf("expected *ProblemDetails, got %T: %v", err, err)
+	}
+	if pd.Detail != "no such widget" {
+		t.Errorf("got detail %q, expected %q", pd.Detail, "no such widget")
+	}
+}
+
+func TestFromResponseValidationProblem(t *testing.T) {
+	body := `{"status":400,"invalid-params":[{"name":"email","reason":"required"}]}`
+	resp := fakeResponse(http.StatusBadRequest, problem.ContentProblemDetails, body)
+	err := problem.FromResponse(resp)
+	var vp *problem.ValidationProblem
+	if !errors.As(err, &vp) {
+		t.Fatalf("expected *ValidationProblem, got %T: %v", err, err)
+	}
+	if len(vp.ValidationErrors) != 1 || vp.ValidationErrors[0].FieldName != "email" {
+		t.Errorf("lost validation errors: %+v", vp.ValidationErrors)
+	}
+}
+
+func TestFromResponseMentioningInvalidParamsIsNotValidationProblem(t *testing.T) {
+	body := `{"status":400,"detail":"the invalid-params field is weird"}`
+	resp := fakeResponse(http.StatusBadRequest, problem.ContentProblemDetails, body)
+	err := problem.FromResponse(resp)
+	var pd *problem.ProblemDetails
+	if !errors.As(err, &pd) {
+		t.Fatalf("expected *ProblemDetails, got %T: %v", err, err)
+	}
+	if pd.Detail != "the invalid-params field is weird" {
+		t.Errorf("got detail %q, expected %q", pd.Detail, "the invalid-params field is weird")
+	}
+}
+
+func TestFromResponseSynthesized(t *testing.T) {
+	resp := fakeResponse(http.StatusInternalServerError, "text/plain", "boom")
+	err := problem.FromResponse(resp)
+	var pd *problem.ProblemDetails
+	if !errors.As(err, &pd) {
+		t.Fatalf("expected *ProblemDetails, got %T: %v", err, err)
+	}
+	if pd.Status != http.StatusInternalServerError {
+		t.Errorf("got status %d, expected %d", pd.Status, http.StatusInternalServerError)
+	}
+	if pd.Detail != "boom" {
+		t.Errorf("got detail %q, expected %q", pd.Detail, "boom")
+	}
+}
+
+func TestFromResponseXML(t *testing.T) {
+	body := `<problem xmlns="urn:ietf:rfc:7807"><status>404</status><detail>no such widget</detail></problem>`
+	resp := fakeResponse(http.StatusNotFound, problem.ContentProblemXML, body)
+	err := problem.FromResponse(resp)
+	var pd *problem.ProblemDetails
+	if !errors.As(err, &pd) {
+		t.Fatalf("expected *ProblemDetails, got %T: %v", err, err)
+	}
+	if pd.Detail != "no such widget" {
+		t.Errorf("got detail %q, expected %q", pd.Detail, "no such widget")
+	}
+}
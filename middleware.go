@@ -0,0 +1,69 @@
+package problem
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ErrorMapper translates an arbitrary error into a ProblemDetails, so
+// domain errors -- sql.ErrNoRows, context.DeadlineExceeded, a validator
+// library's error type, and so on -- can be translated to the right HTTP
+// status in one place, instead of at every call site.
+type ErrorMapper func(error) *ProblemDetails
+
+// Handler adapts a handler function that returns an error into a standard
+// http.Handler. If next returns a non-nil error, it's routed through
+// MustWrite, so the client gets a problem details response instead of the
+// handler having to call Write itself.
+func Handler(next func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := next(w, r); err != nil {
+			MustWrite(w, err)
+		}
+	})
+}
+
+// HandlerWithMapper is like Handler, but first runs any error that isn't
+// already an HTTPError through mapper, so domain errors get translated to
+// the right status before being written.
+func HandlerWithMapper(mapper ErrorMapper, next func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := next(w, r)
+		if err == nil {
+			return
+		}
+		if _, ok := err.(HTTPError); !ok && mapper != nil {
+			if mapped := mapper(err); mapped != nil {
+				err = mapped
+			}
+		}
+		MustWrite(w, err)
+	})
+}
+
+// Recover wraps next with panic recovery: if next panics, Recover builds a
+// 500 Internal Server Error ProblemDetails with the recovered value as
+// detail and writes it, instead of letting the panic propagate and take
+// down the server. If CaptureStack is set, the problem also carries a
+// stack, captured inside the deferred recovery function, before the
+// panicking frames are unwound, so it includes the frames of the panic site.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			var stack []string
+			if CaptureStack {
+				stack = captureStack()
+			}
+			prob := New(http.StatusInternalServerError).WithDetail(fmt.Sprintf("%v", rec))
+			if len(stack) > 0 {
+				prob.WithExtension("stack", stack)
+			}
+			prob.Write(w)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
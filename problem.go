@@ -2,27 +2,102 @@ package problem
 
 import (
 	"encoding/json"
-	"errors"
+	"encoding/xml"
 	"fmt"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // ContentProblemDetails is the correct MIME type to use when returning a
 // problem details object as JSON.
 const ContentProblemDetails = "application/problem+json"
 
+// ContentProblemXML is the correct MIME type to use when returning a
+// problem details object as XML.
+const ContentProblemXML = "application/problem+xml"
+
 // ProblemDetails provides a standard encapsulation for problems encountered
 // in web applications and REST APIs.
 type ProblemDetails struct {
-	Status       int    `json:"status,omitempty"`
-	Title        string `json:"title,omitempty"`
-	Detail       string `json:"detail,omitempty"`
-	Type         string `json:"type,omitempty"`
-	Instance     string `json:"instance,omitempty"`
+	XMLName  xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string   `json:"type,omitempty" xml:"type,omitempty"`
+	Title    string   `json:"title,omitempty" xml:"title,omitempty"`
+	Status   int      `json:"status,omitempty" xml:"status,omitempty"`
+	Detail   string   `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty" xml:"instance,omitempty"`
+	// Extensions holds the arbitrary extension members that RFC 7807 §3.2
+	// allows alongside the five standard fields above. They're flattened
+	// into the top-level JSON object by MarshalJSON, not nested. They
+	// aren't currently represented in the XML encoding.
+	Extensions   map[string]interface{} `json:"-" xml:"-"`
 	wrappedError error
 }
 
+// problemFields lists the JSON keys which are handled by the standard
+// ProblemDetails fields, so UnmarshalJSON knows which top-level keys to
+// collect into Extensions instead.
+var problemFields = map[string]bool{
+	"status":   true,
+	"title":    true,
+	"detail":   true,
+	"type":     true,
+	"instance": true,
+}
+
+// MarshalJSON writes out the standard ProblemDetails fields together with
+// any Extensions, flattened into a single top-level JSON object as required
+// by RFC 7807 §3.2.
+func (pd ProblemDetails) MarshalJSON() ([]byte, error) {
+	type alias ProblemDetails
+	base, err := json.Marshal(alias(pd))
+	if err != nil {
+		return nil, err
+	}
+	if len(pd.Extensions) == 0 {
+		return base, nil
+	}
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range pd.Extensions {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON reads the standard ProblemDetails fields, and collects any
+// other top-level members it finds into Extensions, so that round-tripping
+// a problem details body doesn't lose custom fields.
+func (pd *ProblemDetails) UnmarshalJSON(data []byte) error {
+	type alias ProblemDetails
+	aux := alias{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*pd = ProblemDetails(aux)
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for k, v := range raw {
+		if problemFields[k] {
+			continue
+		}
+		var val interface{}
+		if err := json.Unmarshal(v, &val); err != nil {
+			return err
+		}
+		if pd.Extensions == nil {
+			pd.Extensions = map[string]interface{}{}
+		}
+		pd.Extensions[k] = val
+	}
+	return nil
+}
+
 // HTTPError is the minimal interface needed to be able to Write a problem,
 // defined so that ProblemDetails can be encapsulated and expanded as needed.
 type HTTPError interface {
@@ -95,19 +170,27 @@ var typeForStatus = map[int]string{
 //// Fluent API
 
 // New returns a ProblemDetails error object with the given HTTP status code.
+// If CaptureStack is set, it also captures the caller's stack, as per
+// WithStack.
 func New(status int) *ProblemDetails {
-	return &ProblemDetails{
+	pd := &ProblemDetails{
 		Status: status,
 		Title:  typeForStatus[status],
 		Type:   "https://httpstatuses.com/" + strconv.Itoa(status),
 	}
+	if CaptureStack {
+		pd.WithStack()
+	}
+	return pd
 }
 
-// Errorf uses fmt.Errorf to add a detail message to the ProblemDetails object.
-// It supports the %w verb.
+// Errorf uses fmt.Errorf to add a detail message to the ProblemDetails
+// object. It supports the %w verb, and the resulting error is kept whole as
+// the wrapped error, so errors.Is and errors.As can walk the full chain it
+// carries -- not just the error named directly by %w.
 func (pd *ProblemDetails) Errorf(fmtstr string, args ...interface{}) *ProblemDetails {
 	err := fmt.Errorf(fmtstr, args...)
-	pd.wrappedError = errors.Unwrap(err)
+	pd.wrappedError = err
 	pd.Detail = err.Error()
 	return pd
 }
@@ -128,9 +211,74 @@ func (pd *ProblemDetails) WithErr(err error) *ProblemDetails {
 	return pd
 }
 
+// WithExtension attaches a non-standard extension member to the problem
+// details object, per RFC 7807 §3.2. Extension members are flattened into
+// the top-level JSON object when the problem is written.
+func (pd *ProblemDetails) WithExtension(key string, value interface{}) *ProblemDetails {
+	if pd.Extensions == nil {
+		pd.Extensions = map[string]interface{}{}
+	}
+	pd.Extensions[key] = value
+	return pd
+}
+
+// HeaderWriter is implemented by problems which need to set extra HTTP
+// headers when written, beyond Content-Type and the status line -- for
+// example WWW-Authenticate, Retry-After or Allow. rawWrite checks for it
+// before calling WriteHeader, so the mechanism is open to user-defined
+// problem types too.
+type HeaderWriter interface {
+	WriteHeaders(http.Header)
+}
+
+// acceptsXML reports whether r's Accept header expresses a preference for
+// application/problem+xml over application/problem+json, by quality value.
+func acceptsXML(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+	bestType := ""
+	bestQ := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediatype, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if parsed, perr := strconv.ParseFloat(qs, 64); perr == nil {
+				q = parsed
+			}
+		}
+		switch mediatype {
+		case ContentProblemXML, "application/xml", "text/xml":
+			if q > bestQ {
+				bestQ = q
+				bestType = "xml"
+			}
+		case ContentProblemDetails, "application/json":
+			if q > bestQ {
+				bestQ = q
+				bestType = "json"
+			}
+		}
+	}
+	return bestType == "xml"
+}
+
 // rawWrite implements writing anything which satisfies HTTPError, as a JSON
-// problem details object.
-func rawWrite(w http.ResponseWriter, obj HTTPError) error {
+// or XML problem details object. r is used only to negotiate XML vs JSON by
+// Accept header, and may be nil, in which case JSON is always used.
+func rawWrite(w http.ResponseWriter, r *http.Request, obj HTTPError) error {
+	if hw, ok := obj.(HeaderWriter); ok {
+		hw.WriteHeaders(w.Header())
+	}
+	if r != nil && acceptsXML(r) {
+		w.Header().Set(http.CanonicalHeaderKey("Content-Type"), ContentProblemXML)
+		w.WriteHeader(obj.GetStatus())
+		return xml.NewEncoder(w).Encode(obj)
+	}
 	w.Header().Set(http.CanonicalHeaderKey("Content-Type"), ContentProblemDetails)
 	w.WriteHeader(obj.GetStatus())
 	return json.NewEncoder(w).Encode(obj)
@@ -139,7 +287,14 @@ func rawWrite(w http.ResponseWriter, obj HTTPError) error {
 // Write sets the HTTP response code from the ProblemDetails and then sends the
 // entire object as JSON.
 func (pd *ProblemDetails) Write(w http.ResponseWriter) error {
-	return rawWrite(w, pd)
+	return rawWrite(w, nil, pd)
+}
+
+// WriteTo is like Write, but negotiates JSON vs XML against the request's
+// Accept header, emitting application/problem+xml when the client prefers
+// it and application/problem+json otherwise.
+func (pd *ProblemDetails) WriteTo(w http.ResponseWriter, r *http.Request) error {
+	return rawWrite(w, r, pd)
 }
 
 //// Non-fluent API
@@ -154,7 +309,7 @@ func Write(w http.ResponseWriter, err error) error {
 	/* case ProblemDetails:
 	return r.Write(w) */
 	case HTTPError:
-		return rawWrite(w, r)
+		return rawWrite(w, nil, r)
 	case error:
 		return r
 	default:
@@ -162,6 +317,22 @@ func Write(w http.ResponseWriter, err error) error {
 	}
 }
 
+// WriteTo is like Write, but negotiates JSON vs XML against the request's
+// Accept header, as per ProblemDetails.WriteTo.
+func WriteTo(w http.ResponseWriter, r *http.Request, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case HTTPError:
+		return rawWrite(w, r, v)
+	case error:
+		return v
+	default:
+		return fmt.Errorf("can't write non-error type %T", err)
+	}
+}
+
 // MustWrite is like Write, but if the error isn't a ProblemDetails object
 // the error is written as a new problem details object, HTTP Internal Server
 // Error.
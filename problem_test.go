@@ -3,6 +3,7 @@ package problem_test
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -124,4 +125,99 @@ func TestReport(t *testing.T) {
 	if prob.Detail != errmsg2 {
 		t.Errorf("expected '%s', got '%s'", errmsg2, prob.Detail)
 	}
+}
+
+func TestExtensions(t *testing.T) {
+	prob := problem.New(http.StatusPaymentRequired).WithExtension("balance", 30).WithExtension("accounts", []string{"a", "b"})
+	data, err := json.Marshal(prob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["balance"] != float64(30) {
+		t.Errorf("got balance %v, expected 30", m["balance"])
+	}
+	if _, ok := m["accounts"]; !ok {
+		t.Errorf("got %v, expected accounts extension to be present", m)
+	}
+	got := &problem.ProblemDetails{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Extensions["balance"] != float64(30) {
+		t.Errorf("got balance %v after round trip, expected 30", got.Extensions["balance"])
+	}
+	if got.Status != http.StatusPaymentRequired {
+		t.Errorf("got status %d, expected %d", got.Status, http.StatusPaymentRequired)
+	}
+}
+
+func TestWriteToNegotiatesXML(t *testing.T) {
+	prob := problem.New(http.StatusNotFound).WithDetail("no such page")
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/problem+xml")
+	if err := prob.WriteTo(w, r); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if ct := resp.Header.Get("content-type"); ct != problem.ContentProblemXML {
+		t.Errorf("got content-type %s, expected %s", ct, problem.ContentProblemXML)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "<detail>no such page</detail>") {
+		t.Errorf("got body %s, expected a <detail> element", body)
+	}
+}
+
+func TestWriteToDefaultsToJSON(t *testing.T) {
+	prob := problem.New(http.StatusNotFound).WithDetail("no such page")
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := prob.WriteTo(w, r); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if ct := resp.Header.Get("content-type"); ct != problem.ContentProblemDetails {
+		t.Errorf("got content-type %s, expected %s", ct, problem.ContentProblemDetails)
+	}
+}
+
+func TestErrorsIsThroughWithErr(t *testing.T) {
+	sentinel := errors.New("connection refused")
+	prob := problem.New(http.StatusBadGateway).WithErr(fmt.Errorf("dialing upstream: %w", sentinel))
+	if !errors.Is(prob, sentinel) {
+		t.Errorf("expected errors.Is to find %v through %v", sentinel, prob)
+	}
+}
+
+func TestWithStackAddsExtensionOnlyWhenCalled(t *testing.T) {
+	plain := problem.New(http.StatusInternalServerError)
+	if _, ok := plain.Extensions["stack"]; ok {
+		t.Error("got stack extension without calling WithStack, expected none")
+	}
+	withStack := problem.New(http.StatusInternalServerError).WithStack()
+	data, err := json.Marshal(withStack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["stack"]; !ok {
+		t.Errorf("got %v, expected a stack extension after WithStack", m)
+	}
+}
+
+func TestCaptureStackGlobal(t *testing.T) {
+	problem.CaptureStack = true
+	defer func() { problem.CaptureStack = false }()
+	prob := problem.New(http.StatusInternalServerError)
+	if _, ok := prob.Extensions["stack"]; !ok {
+		t.Error("expected New to capture a stack when CaptureStack is true")
+	}
 }
\ No newline at end of file
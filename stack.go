@@ -0,0 +1,56 @@
+package problem
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// CaptureStack controls whether New automatically captures a stack trace
+// on every problem it creates. It defaults to false: stack traces are an
+// implementation detail that shouldn't leak to clients by default. Enable
+// it globally for server-side diagnostics, or call WithStack on individual
+// problems instead.
+var CaptureStack bool
+
+// maxStackFrames bounds how many call frames are walked when capturing a
+// stack trace.
+const maxStackFrames = 32
+
+// WithStack captures the current call stack and attaches it to the problem
+// as the non-standard "stack" extension member (see WithExtension), so it
+// only appears in JSON when explicitly opted in -- never by default.
+func (pd *ProblemDetails) WithStack() *ProblemDetails {
+	if frames := captureStack(); len(frames) > 0 {
+		pd.WithExtension("stack", frames)
+	}
+	return pd
+}
+
+// captureStack walks the call stack via runtime.Callers, skipping frames
+// inside this package and the Go runtime, and formats each remaining frame
+// as "file:line function".
+func captureStack() []string {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(0, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var out []string
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame) {
+			out = append(out, fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function))
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// isInternalFrame reports whether frame belongs to the Go runtime or to
+// this package, so captureStack can skip over the plumbing frames for
+// runtime.Callers, WithStack, New and Errorf themselves.
+func isInternalFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, "runtime.") ||
+		strings.HasPrefix(frame.Function, "github.com/lpar/problem.")
+}
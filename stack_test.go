@@ -0,0 +1,25 @@
+package problem_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lpar/problem"
+)
+
+func TestWithStackFramesExcludePackageInternals(t *testing.T) {
+	prob := problem.New(http.StatusInternalServerError).WithStack()
+	frames, ok := prob.Extensions["stack"].([]string)
+	if !ok || len(frames) == 0 {
+		t.Fatalf("got %v, expected a non-empty []string stack", prob.Extensions["stack"])
+	}
+	for _, f := range frames {
+		if strings.Contains(f, "lpar/problem.") {
+			t.Errorf("got frame %q, expected package-internal frames to be skipped", f)
+		}
+	}
+	if !strings.Contains(frames[0], "stack_test.go") {
+		t.Errorf("got top frame %q, expected it to point into this test file", frames[0])
+	}
+}
@@ -0,0 +1,162 @@
+package problem
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NotFoundf returns a 404 Not Found ProblemDetails, with detail formatted as
+// per fmt.Errorf. It supports the %w verb.
+func NotFoundf(fmtstr string, args ...interface{}) *ProblemDetails {
+	return Errorf(http.StatusNotFound, fmtstr, args...)
+}
+
+// Forbiddenf returns a 403 Forbidden ProblemDetails, with detail formatted
+// as per fmt.Errorf. It supports the %w verb.
+func Forbiddenf(fmtstr string, args ...interface{}) *ProblemDetails {
+	return Errorf(http.StatusForbidden, fmtstr, args...)
+}
+
+// Conflictf returns a 409 Conflict ProblemDetails, with detail formatted as
+// per fmt.Errorf. It supports the %w verb.
+func Conflictf(fmtstr string, args ...interface{}) *ProblemDetails {
+	return Errorf(http.StatusConflict, fmtstr, args...)
+}
+
+// BadGatewayf returns a 502 Bad Gateway ProblemDetails, with detail
+// formatted as per fmt.Errorf. It supports the %w verb.
+func BadGatewayf(fmtstr string, args ...interface{}) *ProblemDetails {
+	return Errorf(http.StatusBadGateway, fmtstr, args...)
+}
+
+// UnauthorizedProblem is a 401 Unauthorized ProblemDetails which also
+// carries a WWW-Authenticate challenge. Write sends the challenge as the
+// WWW-Authenticate header alongside the JSON body.
+type UnauthorizedProblem struct {
+	ProblemDetails
+	Challenge string `json:"-" xml:"-"`
+}
+
+// WriteHeaders implements HeaderWriter, setting WWW-Authenticate from the
+// configured challenge.
+func (p *UnauthorizedProblem) WriteHeaders(h http.Header) {
+	if p.Challenge != "" {
+		h.Set("WWW-Authenticate", p.Challenge)
+	}
+}
+
+// Write sets the HTTP response code and sends the entire object as JSON,
+// including the WWW-Authenticate header. It's defined explicitly because
+// the embedded ProblemDetails.Write would otherwise be promoted and pass
+// only the embedded *ProblemDetails to rawWrite, losing the Challenge.
+func (p *UnauthorizedProblem) Write(w http.ResponseWriter) error {
+	return rawWrite(w, nil, p)
+}
+
+// WriteTo is like Write, but negotiates JSON vs XML against the request's
+// Accept header, as per ProblemDetails.WriteTo.
+func (p *UnauthorizedProblem) WriteTo(w http.ResponseWriter, r *http.Request) error {
+	return rawWrite(w, r, p)
+}
+
+// Unauthorizedf returns a 401 Unauthorized problem carrying the given
+// WWW-Authenticate challenge (e.g. `Bearer realm="api"`), with detail
+// formatted as per fmt.Errorf. It supports the %w verb.
+func Unauthorizedf(challenge string, fmtstr string, args ...interface{}) *UnauthorizedProblem {
+	return &UnauthorizedProblem{
+		ProblemDetails: *Errorf(http.StatusUnauthorized, fmtstr, args...),
+		Challenge:      challenge,
+	}
+}
+
+// RetryAfterProblem is a ProblemDetails which also carries a Retry-After
+// duration. Write sends it as the Retry-After header, in whole seconds,
+// alongside the JSON body.
+type RetryAfterProblem struct {
+	ProblemDetails
+	RetryAfter time.Duration `json:"-" xml:"-"`
+}
+
+// WriteHeaders implements HeaderWriter, setting Retry-After from the
+// configured duration.
+func (p *RetryAfterProblem) WriteHeaders(h http.Header) {
+	if p.RetryAfter > 0 {
+		h.Set("Retry-After", strconv.Itoa(int(p.RetryAfter.Seconds())))
+	}
+}
+
+// Write sets the HTTP response code and sends the entire object as JSON,
+// including the Retry-After header. It's defined explicitly because the
+// embedded ProblemDetails.Write would otherwise be promoted and pass only
+// the embedded *ProblemDetails to rawWrite, losing RetryAfter.
+func (p *RetryAfterProblem) Write(w http.ResponseWriter) error {
+	return rawWrite(w, nil, p)
+}
+
+// WriteTo is like Write, but negotiates JSON vs XML against the request's
+// Accept header, as per ProblemDetails.WriteTo.
+func (p *RetryAfterProblem) WriteTo(w http.ResponseWriter, r *http.Request) error {
+	return rawWrite(w, r, p)
+}
+
+// TooManyRequestsf returns a 429 Too Many Requests problem carrying the
+// given Retry-After duration, with detail formatted as per fmt.Errorf. It
+// supports the %w verb.
+func TooManyRequestsf(retryAfter time.Duration, fmtstr string, args ...interface{}) *RetryAfterProblem {
+	return &RetryAfterProblem{
+		ProblemDetails: *Errorf(http.StatusTooManyRequests, fmtstr, args...),
+		RetryAfter:     retryAfter,
+	}
+}
+
+// ServiceUnavailablef returns a 503 Service Unavailable problem carrying the
+// given Retry-After duration, with detail formatted as per fmt.Errorf. It
+// supports the %w verb.
+func ServiceUnavailablef(retryAfter time.Duration, fmtstr string, args ...interface{}) *RetryAfterProblem {
+	return &RetryAfterProblem{
+		ProblemDetails: *Errorf(http.StatusServiceUnavailable, fmtstr, args...),
+		RetryAfter:     retryAfter,
+	}
+}
+
+// MethodNotAllowedProblem is a 405 Method Not Allowed ProblemDetails which
+// also carries the set of methods the resource does allow. Write sends them
+// as the Allow header alongside the JSON body.
+type MethodNotAllowedProblem struct {
+	ProblemDetails
+	AllowedMethods []string `json:"-" xml:"-"`
+}
+
+// WriteHeaders implements HeaderWriter, setting Allow from the configured
+// methods.
+func (p *MethodNotAllowedProblem) WriteHeaders(h http.Header) {
+	if len(p.AllowedMethods) > 0 {
+		h.Set("Allow", strings.Join(p.AllowedMethods, ", "))
+	}
+}
+
+// Write sets the HTTP response code and sends the entire object as JSON,
+// including the Allow header. It's defined explicitly because the embedded
+// ProblemDetails.Write would otherwise be promoted and pass only the
+// embedded *ProblemDetails to rawWrite, losing AllowedMethods.
+func (p *MethodNotAllowedProblem) Write(w http.ResponseWriter) error {
+	return rawWrite(w, nil, p)
+}
+
+// WriteTo is like Write, but negotiates JSON vs XML against the request's
+// Accept header, as per ProblemDetails.WriteTo.
+func (p *MethodNotAllowedProblem) WriteTo(w http.ResponseWriter, r *http.Request) error {
+	return rawWrite(w, r, p)
+}
+
+// MethodNotAllowedf returns a 405 Method Not Allowed problem carrying the
+// given allowed methods, with detail formatted as per fmt.Errorf. It
+// supports the %w verb.
+func MethodNotAllowedf(allowed []string, fmtstr string, args ...interface{}) *MethodNotAllowedProblem {
+	return &MethodNotAllowedProblem{
+		ProblemDetails: *Errorf(http.StatusMethodNotAllowed, fmtstr, args...),
+		AllowedMethods: allowed,
+	}
+}
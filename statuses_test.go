@@ -0,0 +1,93 @@
+package problem_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lpar/problem"
+)
+
+func TestUnauthorizedfSetsChallenge(t *testing.T) {
+	prob := problem.Unauthorizedf(`Bearer realm="api"`, "no token supplied")
+	w := httptest.NewRecorder()
+	problem.MustWrite(w, prob)
+	resp := w.Result()
+	if got := resp.Header.Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+		t.Errorf("got WWW-Authenticate %q, expected %q", got, `Bearer realm="api"`)
+	}
+}
+
+func TestTooManyRequestsfSetsRetryAfter(t *testing.T) {
+	prob := problem.TooManyRequestsf(30*time.Second, "slow down")
+	w := httptest.NewRecorder()
+	problem.MustWrite(w, prob)
+	resp := w.Result()
+	if got := resp.Header.Get("Retry-After"); got != "30" {
+		t.Errorf("got Retry-After %q, expected %q", got, "30")
+	}
+}
+
+func TestMethodNotAllowedfSetsAllow(t *testing.T) {
+	prob := problem.MethodNotAllowedf([]string{"GET", "HEAD"}, "use GET")
+	w := httptest.NewRecorder()
+	problem.MustWrite(w, prob)
+	resp := w.Result()
+	if got := resp.Header.Get("Allow"); got != "GET, HEAD" {
+		t.Errorf("got Allow %q, expected %q", got, "GET, HEAD")
+	}
+}
+
+// These typed problems carry headers that only the rawWrite path knows how
+// to emit, so Write (and WriteTo) must be overridden on each concrete type
+// rather than relying on the one promoted from the embedded ProblemDetails,
+// which would write only the embedded value and silently drop the header.
+
+func TestUnauthorizedfFluentWriteSetsChallenge(t *testing.T) {
+	prob := problem.Unauthorizedf(`Bearer realm="api"`, "no token supplied")
+	w := httptest.NewRecorder()
+	if err := prob.Write(w); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if got := resp.Header.Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+		t.Errorf("got WWW-Authenticate %q, expected %q", got, `Bearer realm="api"`)
+	}
+}
+
+func TestTooManyRequestsfFluentWriteSetsRetryAfter(t *testing.T) {
+	prob := problem.TooManyRequestsf(30*time.Second, "slow down")
+	w := httptest.NewRecorder()
+	if err := prob.Write(w); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if got := resp.Header.Get("Retry-After"); got != "30" {
+		t.Errorf("got Retry-After %q, expected %q", got, "30")
+	}
+}
+
+func TestMethodNotAllowedfFluentWriteSetsAllow(t *testing.T) {
+	prob := problem.MethodNotAllowedf([]string{"GET", "HEAD"}, "use GET")
+	w := httptest.NewRecorder()
+	if err := prob.Write(w); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if got := resp.Header.Get("Allow"); got != "GET, HEAD" {
+		t.Errorf("got Allow %q, expected %q", got, "GET, HEAD")
+	}
+}
+
+func TestUnauthorizedfFluentWriteToSetsChallenge(t *testing.T) {
+	prob := problem.Unauthorizedf(`Bearer realm="api"`, "no token supplied")
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := prob.WriteTo(w, r); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	if got := resp.Header.Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+		t.Errorf("got WWW-Authenticate %q, expected %q", got, `Bearer realm="api"`)
+	}
+}
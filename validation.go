@@ -1,6 +1,7 @@
 package problem
 
 import (
+	"encoding/json"
 	"net/http"
 )
 
@@ -9,14 +10,65 @@ import (
 // reporting of server-side data validation errors.
 type ValidationProblem struct {
 	ProblemDetails
-	ValidationErrors []ValidationError `json:"invalid-params,omitempty"`
+	ValidationErrors []ValidationError `json:"invalid-params,omitempty" xml:"invalid-params"`
 }
 
-// ValidationError indicates a server-side validation error for data submitted
-// as JSON or via a web form.
+// ValidationError indicates one or more server-side validation errors for a
+// single field of data submitted as JSON or via a web form.
 type ValidationError struct {
-	FieldName string `json:"name"`
-	Error     string `json:"reason"`
+	FieldName string `json:"name" xml:"name"`
+	// Reasons holds every failure reason recorded for this field. When
+	// there's exactly one, MarshalJSON writes it using the original
+	// singular "reason" field for backward JSON compatibility; otherwise
+	// it writes the plural "reasons" array. In XML each reason is always
+	// its own repeated <reason> element.
+	Reasons []string `json:"-" xml:"reason"`
+}
+
+// Reason returns the first recorded failure reason for the field, which is
+// all there is when the field has exactly one. It's a convenience for the
+// common single-reason case.
+func (ve ValidationError) Reason() string {
+	if len(ve.Reasons) == 0 {
+		return ""
+	}
+	return ve.Reasons[0]
+}
+
+// MarshalJSON writes the field name together with its failure reason(s),
+// using the singular "reason" field when there's only one, for backward
+// compatibility with the original ValidationError JSON shape.
+func (ve ValidationError) MarshalJSON() ([]byte, error) {
+	if len(ve.Reasons) == 1 {
+		return json.Marshal(struct {
+			FieldName string `json:"name"`
+			Reason    string `json:"reason"`
+		}{ve.FieldName, ve.Reasons[0]})
+	}
+	return json.Marshal(struct {
+		FieldName string   `json:"name"`
+		Reasons   []string `json:"reasons,omitempty"`
+	}{ve.FieldName, ve.Reasons})
+}
+
+// UnmarshalJSON reads either the singular "reason" field or the plural
+// "reasons" array into Reasons.
+func (ve *ValidationError) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		FieldName string   `json:"name"`
+		Reason    string   `json:"reason"`
+		Reasons   []string `json:"reasons"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	ve.FieldName = aux.FieldName
+	if len(aux.Reasons) > 0 {
+		ve.Reasons = aux.Reasons
+	} else if aux.Reason != "" {
+		ve.Reasons = []string{aux.Reason}
+	}
+	return nil
 }
 
 // NewValidationProblem creates an object to represent a server-side validation error.
@@ -27,8 +79,87 @@ func NewValidationProblem() *ValidationProblem{
 	}
 }
 
-// Add adds a validation error message for the specified field to the ValidationProblem.
+// Add adds a validation error message for the specified field to the
+// ValidationProblem. If the field already has one or more recorded errors,
+// the message is grouped in as an additional reason rather than creating a
+// second invalid-params entry for the same field.
 func (vp *ValidationProblem) Add(field string, errmsg string) {
-	ve := ValidationError{field, errmsg}
-	vp.ValidationErrors = append(vp.ValidationErrors, ve)
+	for i := range vp.ValidationErrors {
+		if vp.ValidationErrors[i].FieldName == field {
+			vp.ValidationErrors[i].Reasons = append(vp.ValidationErrors[i].Reasons, errmsg)
+			return
+		}
+	}
+	vp.ValidationErrors = append(vp.ValidationErrors, ValidationError{FieldName: field, Reasons: []string{errmsg}})
+}
+
+// AddMany adds several failure reasons for the specified field in one call,
+// grouping them under a single invalid-params entry alongside any reasons
+// already recorded for that field.
+func (vp *ValidationProblem) AddMany(field string, reasons ...string) {
+	for _, r := range reasons {
+		vp.Add(field, r)
+	}
+}
+
+// Err returns the ValidationProblem as an error, but only if it has at
+// least one recorded validation error; otherwise it returns nil. This lets
+// callers write `if err := vp.Err(); err != nil { return err }` after
+// running a batch of validators.
+func (vp *ValidationProblem) Err() error {
+	if len(vp.ValidationErrors) == 0 {
+		return nil
+	}
+	return vp
+}
+
+// MarshalJSON writes out the embedded ProblemDetails fields (including any
+// Extensions) together with the invalid-params list. It's defined explicitly
+// because ProblemDetails.MarshalJSON would otherwise be promoted and take
+// over marshaling of the whole ValidationProblem, dropping ValidationErrors.
+func (vp ValidationProblem) MarshalJSON() ([]byte, error) {
+	base, err := json.Marshal(vp.ProblemDetails)
+	if err != nil {
+		return nil, err
+	}
+	if len(vp.ValidationErrors) == 0 {
+		return base, nil
+	}
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	merged["invalid-params"] = vp.ValidationErrors
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON reads the embedded ProblemDetails fields together with the
+// invalid-params list, for the same reason MarshalJSON is defined explicitly.
+func (vp *ValidationProblem) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &vp.ProblemDetails); err != nil {
+		return err
+	}
+	delete(vp.ProblemDetails.Extensions, "invalid-params")
+	aux := struct {
+		ValidationErrors []ValidationError `json:"invalid-params,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	vp.ValidationErrors = aux.ValidationErrors
+	return nil
+}
+
+// Write sets the HTTP response code and sends the entire object as JSON,
+// including the invalid-params list. It's defined explicitly because the
+// embedded ProblemDetails.Write would otherwise be promoted and pass only
+// the embedded *ProblemDetails to rawWrite, dropping ValidationErrors.
+func (vp *ValidationProblem) Write(w http.ResponseWriter) error {
+	return rawWrite(w, nil, vp)
+}
+
+// WriteTo is like Write, but negotiates JSON vs XML against the request's
+// Accept header, as per ProblemDetails.WriteTo.
+func (vp *ValidationProblem) WriteTo(w http.ResponseWriter, r *http.Request) error {
+	return rawWrite(w, r, vp)
 }
@@ -44,10 +44,104 @@ func TestNewValidationProblem(t *testing.T) {
 		email = p2
 		name = p1
 	}
-	if email.FieldName != "email" || email.Error != msg1 {
+	if email.FieldName != "email" || email.Reason() != msg1 {
 		t.Errorf("lost/corrupted email field validation message")
 	}
-	if name.FieldName != "name" || name.Error != msg2 {
+	if name.FieldName != "name" || name.Reason() != msg2 {
 		t.Errorf("lost/corrupted name field validation message")
 	}
 }
+
+func TestValidationProblemMultipleReasons(t *testing.T) {
+	const msg3 = "Must be lower case"
+	valerr := problem.NewValidationProblem()
+	valerr.AddMany("email", msg1, msg3)
+	data, err := json.Marshal(valerr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &problem.ValidationProblem{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.ValidationErrors) != 1 {
+		t.Fatalf("got %d errors, expected 1", len(got.ValidationErrors))
+	}
+	ve := got.ValidationErrors[0]
+	if len(ve.Reasons) != 2 || ve.Reasons[0] != msg1 || ve.Reasons[1] != msg3 {
+		t.Errorf("got reasons %v, expected [%q %q]", ve.Reasons, msg1, msg3)
+	}
+}
+
+func TestValidationProblemErr(t *testing.T) {
+	valerr := problem.NewValidationProblem()
+	if err := valerr.Err(); err != nil {
+		t.Errorf("expected nil error for empty ValidationProblem, got %v", err)
+	}
+	valerr.Add("email", msg1)
+	if err := valerr.Err(); err == nil {
+		t.Error("expected non-nil error once a validation error is recorded")
+	}
+}
+
+func TestValidationProblemWithExtension(t *testing.T) {
+	valerr := problem.NewValidationProblem()
+	valerr.Add("email", msg1)
+	valerr.WithExtension("trace_id", "abc123")
+	data, err := json.Marshal(valerr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &problem.ValidationProblem{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.ValidationErrors) != 1 {
+		t.Fatalf("got %d errors, expected 1", len(got.ValidationErrors))
+	}
+	if got.Extensions["trace_id"] != "abc123" {
+		t.Errorf("got trace_id %v, expected abc123", got.Extensions["trace_id"])
+	}
+}
+
+// The embedded ProblemDetails.Write/WriteTo would otherwise be promoted and
+// write only the embedded *ProblemDetails, silently dropping invalid-params,
+// so ValidationProblem must override both; these tests call the fluent
+// methods directly rather than going through MustWrite.
+
+func TestValidationProblemFluentWrite(t *testing.T) {
+	valerr := problem.NewValidationProblem()
+	valerr.Add("email", msg1)
+	w := httptest.NewRecorder()
+	if err := valerr.Write(w); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	prob := problem.ValidationProblem{}
+	if err := json.Unmarshal(body, &prob); err != nil {
+		t.Fatal(err)
+	}
+	if len(prob.ValidationErrors) != 1 || prob.ValidationErrors[0].FieldName != "email" {
+		t.Errorf("got %+v, expected invalid-params to survive the fluent Write", prob.ValidationErrors)
+	}
+}
+
+func TestValidationProblemFluentWriteTo(t *testing.T) {
+	valerr := problem.NewValidationProblem()
+	valerr.Add("email", msg1)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := valerr.WriteTo(w, r); err != nil {
+		t.Fatal(err)
+	}
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	prob := problem.ValidationProblem{}
+	if err := json.Unmarshal(body, &prob); err != nil {
+		t.Fatal(err)
+	}
+	if len(prob.ValidationErrors) != 1 || prob.ValidationErrors[0].FieldName != "email" {
+		t.Errorf("got %+v, expected invalid-params to survive the fluent WriteTo", prob.ValidationErrors)
+	}
+}